@@ -0,0 +1,553 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// defaultObjectAsOptions is used for every types.Object.As call in this file;
+// none of the nested models here round-trip through unhandled null/unknown
+// attributes so the framework's default strictness is what we want.
+var defaultObjectAsOptions = basetypes.ObjectAsOptions{}
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &AutounattendXMLResource{}
+var _ resource.ResourceWithConfigValidators = &AutounattendXMLResource{}
+
+func NewAutounattendXMLResource() resource.Resource {
+	return &AutounattendXMLResource{}
+}
+
+// AutounattendXMLResource renders a Windows Autounattend.xml from typed
+// inputs, so that unattend_iso_file doesn't force users to hand-author XML.
+type AutounattendXMLResource struct {
+}
+
+// AutounattendXMLResourceModel describes the resource data model.
+type AutounattendXMLResourceModel struct {
+	Id                     types.String `tfsdk:"id"`
+	WindowsEdition         types.String `tfsdk:"windows_edition"`
+	ProductKey             types.String `tfsdk:"product_key"`
+	ComputerName           types.String `tfsdk:"computer_name"`
+	Locale                 types.String `tfsdk:"locale"`
+	Timezone               types.String `tfsdk:"timezone"`
+	DiskConfiguration      types.List   `tfsdk:"disk_configuration"`
+	UserAccounts           types.List   `tfsdk:"user_accounts"`
+	FirstLogonCommands     types.List   `tfsdk:"first_logon_commands"`
+	RunSynchronousCommands types.List   `tfsdk:"run_synchronous_commands"`
+	Network                types.Object `tfsdk:"network"`
+	OOBE                   types.Object `tfsdk:"oobe"`
+	XMLContent             types.String `tfsdk:"xml_content"`
+}
+
+type diskPartitionModel struct {
+	Type   types.String `tfsdk:"type"`
+	SizeMB types.Int64  `tfsdk:"size_mb"`
+	Format types.String `tfsdk:"format"`
+	Letter types.String `tfsdk:"letter"`
+}
+
+type userAccountModel struct {
+	Name      types.String `tfsdk:"name"`
+	Password  types.String `tfsdk:"password"`
+	Group     types.String `tfsdk:"group"`
+	AutoLogon types.Bool   `tfsdk:"auto_logon"`
+}
+
+type networkModel struct {
+	DHCP       types.Bool   `tfsdk:"dhcp"`
+	StaticIP   types.String `tfsdk:"static_ip"`
+	SubnetMask types.String `tfsdk:"subnet_mask"`
+	Gateway    types.String `tfsdk:"gateway"`
+	DNSServers types.List   `tfsdk:"dns_servers"`
+	Workgroup  types.String `tfsdk:"workgroup"`
+	DomainJoin types.Object `tfsdk:"domain_join"`
+}
+
+type domainJoinModel struct {
+	Domain   types.String `tfsdk:"domain"`
+	OU       types.String `tfsdk:"ou"`
+	Username types.String `tfsdk:"username"`
+	Password types.String `tfsdk:"password"`
+}
+
+type oobeModel struct {
+	SkipEULA             types.Bool `tfsdk:"skip_eula"`
+	SkipNetwork          types.Bool `tfsdk:"skip_network"`
+	SkipMicrosoftAccount types.Bool `tfsdk:"skip_microsoft_account"`
+}
+
+func (r *AutounattendXMLResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_autounattend_xml"
+}
+
+func (r *AutounattendXMLResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Renders a Windows `Autounattend.xml` from structured inputs. Pipe the resulting `xml_content` into `unattend_iso_file` to avoid hand-authoring XML.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Autounattend identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"windows_edition": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Edition image name to install, e.g. `Windows 11 Pro`, matched against the install.wim `<ImageInstall>` entry.",
+			},
+			"product_key": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Product key entered in the `windowsPE` pass. Omit to let Setup prompt or use a volume-licensed image.",
+			},
+			"computer_name": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Computer name set in the `specialize` pass. Defaults to `*` (Setup generates a random name).",
+				Default:             stringdefault.StaticString("*"),
+			},
+			"locale": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "UI language and locale, e.g. `en-US`.",
+				Default:             stringdefault.StaticString("en-US"),
+			},
+			"timezone": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Windows timezone name, e.g. `UTC` or `Pacific Standard Time`.",
+				Default:             stringdefault.StaticString("UTC"),
+			},
+			"disk_configuration": schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Ordered list of partitions to create in the `windowsPE` pass's `DiskConfiguration`.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Partition role: `efi`, `msr`, `primary`, or `recovery`.",
+						},
+						"size_mb": schema.Int64Attribute{
+							Optional:            true,
+							MarkdownDescription: "Partition size in MiB. Omit to extend the partition to fill remaining disk space.",
+						},
+						"format": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Filesystem to format the partition with: `ntfs` or `fat32`.",
+						},
+						"letter": schema.StringAttribute{
+							Optional:            true,
+							MarkdownDescription: "Drive letter to assign, e.g. `C`.",
+						},
+					},
+				},
+			},
+			"user_accounts": schema.ListNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Local accounts to create in the `oobeSystem` pass.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Account name.",
+						},
+						"password": schema.StringAttribute{
+							Required:            true,
+							Sensitive:           true,
+							MarkdownDescription: "Account password, embedded as plain text per the unattend schema.",
+						},
+						"group": schema.StringAttribute{
+							Optional:            true,
+							Computed:            true,
+							MarkdownDescription: "Local group the account is added to.",
+							Default:             stringdefault.StaticString("Administrators"),
+						},
+						"auto_logon": schema.BoolAttribute{
+							Optional:            true,
+							Computed:            true,
+							MarkdownDescription: "Whether this account is logged in automatically at first boot.",
+							Default:             booldefault.StaticBool(false),
+						},
+					},
+				},
+			},
+			"first_logon_commands": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Commands run once, as the logged-on user, the first time a user signs in (`FirstLogonCommands`).",
+			},
+			"run_synchronous_commands": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Commands run synchronously during the `specialize` pass, before any user signs in.",
+			},
+			"network": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Network configuration. Set either `dhcp` or the static fields, and either `domain_join` or `workgroup`, not both.",
+				Attributes: map[string]schema.Attribute{
+					"dhcp": schema.BoolAttribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: "Use DHCP instead of the static fields below.",
+						Default:             booldefault.StaticBool(true),
+					},
+					"static_ip": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Static IPv4 address. Requires `dhcp = false`.",
+					},
+					"subnet_mask": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Static IPv4 subnet mask.",
+					},
+					"gateway": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Static IPv4 default gateway.",
+					},
+					"dns_servers": schema.ListAttribute{
+						Optional:            true,
+						ElementType:         types.StringType,
+						MarkdownDescription: "DNS server addresses.",
+					},
+					"workgroup": schema.StringAttribute{
+						Optional:            true,
+						MarkdownDescription: "Workgroup name. Mutually exclusive with `domain_join`.",
+					},
+					"domain_join": schema.SingleNestedAttribute{
+						Optional:            true,
+						MarkdownDescription: "Join an Active Directory domain instead of a workgroup. Mutually exclusive with `workgroup`.",
+						Attributes: map[string]schema.Attribute{
+							"domain": schema.StringAttribute{
+								Required:            true,
+								MarkdownDescription: "Domain to join.",
+							},
+							"ou": schema.StringAttribute{
+								Optional:            true,
+								MarkdownDescription: "Distinguished name of the target organizational unit.",
+							},
+							"username": schema.StringAttribute{
+								Required:            true,
+								MarkdownDescription: "Domain account allowed to join computers to the domain.",
+							},
+							"password": schema.StringAttribute{
+								Required:            true,
+								Sensitive:           true,
+								MarkdownDescription: "Password for the domain join account.",
+							},
+						},
+					},
+				},
+			},
+			"oobe": schema.SingleNestedAttribute{
+				Optional:            true,
+				MarkdownDescription: "Out-of-box experience toggles applied in the `oobeSystem` pass.",
+				Attributes: map[string]schema.Attribute{
+					"skip_eula": schema.BoolAttribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: "Skip the EULA acceptance screen.",
+						Default:             booldefault.StaticBool(true),
+					},
+					"skip_network": schema.BoolAttribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: "Skip the \"connect to a network\" screen.",
+						Default:             booldefault.StaticBool(true),
+					},
+					"skip_microsoft_account": schema.BoolAttribute{
+						Optional:            true,
+						Computed:            true,
+						MarkdownDescription: "Skip prompting for a Microsoft account and force a local account instead.",
+						Default:             booldefault.StaticBool(true),
+					},
+				},
+			},
+			"xml_content": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Rendered `Autounattend.xml`. Pass this into `unattend_iso_file`'s `xml_content`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *AutounattendXMLResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.Conflicting(
+			path.MatchRoot("network").AtName("domain_join"),
+			path.MatchRoot("network").AtName("workgroup"),
+		),
+	}
+}
+
+func (r *AutounattendXMLResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data AutounattendXMLResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	xmlContent, diags := renderAutounattendXML(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	data.Id = types.StringValue(fmt.Sprintf("autounattend-%s", data.ComputerName.ValueString()))
+	data.XMLContent = types.StringValue(xmlContent)
+
+	tflog.Trace(ctx, "rendered autounattend.xml")
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AutounattendXMLResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data AutounattendXMLResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AutounattendXMLResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data AutounattendXMLResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	xmlContent, diags := renderAutounattendXML(ctx, &data)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	data.XMLContent = types.StringValue(xmlContent)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *AutounattendXMLResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data AutounattendXMLResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+}
+
+// renderAutounattendXML assembles a Windows Autounattend.xml document from
+// the resource's typed configuration, with the windowsPE, specialize, and
+// oobeSystem passes populated from the corresponding attributes.
+func renderAutounattendXML(ctx context.Context, data *AutounattendXMLResourceModel) (string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<unattend xmlns="urn:schemas-microsoft-com:unattend">` + "\n")
+
+	// windowsPE pass: disk layout, image selection, product key.
+	b.WriteString(`  <settings pass="windowsPE">` + "\n")
+	b.WriteString(`    <component name="Microsoft-Windows-Setup" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">` + "\n")
+
+	var partitions []diskPartitionModel
+	diags = append(diags, data.DiskConfiguration.ElementsAs(ctx, &partitions, false)...)
+	if len(partitions) > 0 {
+		b.WriteString(`      <DiskConfiguration>` + "\n")
+		b.WriteString(`        <Disk wcm:action="add">` + "\n")
+		b.WriteString(`          <DiskID>0</DiskID>` + "\n")
+		b.WriteString(`          <WillWipeDisk>true</WillWipeDisk>` + "\n")
+		b.WriteString(`          <CreatePartitions>` + "\n")
+		for i, p := range partitions {
+			b.WriteString(`            <CreatePartition wcm:action="add">` + "\n")
+			fmt.Fprintf(&b, "              <Order>%d</Order>\n", i+1)
+			fmt.Fprintf(&b, "              <Type>%s</Type>\n", xmlEscape(p.Type.ValueString()))
+			if !p.SizeMB.IsNull() && p.SizeMB.ValueInt64() > 0 {
+				fmt.Fprintf(&b, "              <Size>%d</Size>\n", p.SizeMB.ValueInt64())
+			} else {
+				b.WriteString(`              <Extend>true</Extend>` + "\n")
+			}
+			b.WriteString(`            </CreatePartition>` + "\n")
+		}
+		b.WriteString(`          </CreatePartitions>` + "\n")
+		b.WriteString(`          <ModifyPartitions>` + "\n")
+		for i, p := range partitions {
+			b.WriteString(`            <ModifyPartition wcm:action="add">` + "\n")
+			fmt.Fprintf(&b, "              <Order>%d</Order>\n", i+1)
+			fmt.Fprintf(&b, "              <PartitionID>%d</PartitionID>\n", i+1)
+			fmt.Fprintf(&b, "              <Format>%s</Format>\n", xmlEscape(strings.ToUpper(p.Format.ValueString())))
+			if !p.Letter.IsNull() && p.Letter.ValueString() != "" {
+				fmt.Fprintf(&b, "              <Letter>%s</Letter>\n", xmlEscape(p.Letter.ValueString()))
+			}
+			b.WriteString(`            </ModifyPartition>` + "\n")
+		}
+		b.WriteString(`          </ModifyPartitions>` + "\n")
+		b.WriteString(`        </Disk>` + "\n")
+		b.WriteString(`      </DiskConfiguration>` + "\n")
+	}
+
+	b.WriteString(`      <ImageInstall>` + "\n")
+	b.WriteString(`        <OSImage>` + "\n")
+	b.WriteString(`          <InstallFrom>` + "\n")
+	fmt.Fprintf(&b, "            <MetaData wcm:action=\"add\">\n              <Key>/IMAGE/NAME</Key>\n              <Value>%s</Value>\n            </MetaData>\n", xmlEscape(data.WindowsEdition.ValueString()))
+	b.WriteString(`          </InstallFrom>` + "\n")
+	b.WriteString(`        </OSImage>` + "\n")
+	b.WriteString(`      </ImageInstall>` + "\n")
+
+	if !data.ProductKey.IsNull() && data.ProductKey.ValueString() != "" {
+		fmt.Fprintf(&b, "      <UserData>\n        <ProductKey>\n          <Key>%s</Key>\n        </ProductKey>\n      </UserData>\n", xmlEscape(data.ProductKey.ValueString()))
+	}
+
+	b.WriteString(`    </component>` + "\n")
+	b.WriteString(`  </settings>` + "\n")
+
+	// specialize pass: computer name, locale, timezone, domain/workgroup, sync commands.
+	b.WriteString(`  <settings pass="specialize">` + "\n")
+	b.WriteString(`    <component name="Microsoft-Windows-Shell-Setup" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">` + "\n")
+	fmt.Fprintf(&b, "      <ComputerName>%s</ComputerName>\n", xmlEscape(data.ComputerName.ValueString()))
+	fmt.Fprintf(&b, "      <TimeZone>%s</TimeZone>\n", xmlEscape(data.Timezone.ValueString()))
+	b.WriteString(`    </component>` + "\n")
+
+	if !data.Network.IsNull() {
+		var network networkModel
+		diags = append(diags, data.Network.As(ctx, &network, defaultObjectAsOptions)...)
+
+		if !network.DomainJoin.IsNull() {
+			var dj domainJoinModel
+			diags = append(diags, network.DomainJoin.As(ctx, &dj, defaultObjectAsOptions)...)
+			b.WriteString(`    <component name="Microsoft-Windows-UnattendedJoin" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">` + "\n")
+			b.WriteString(`      <Identification>` + "\n")
+			fmt.Fprintf(&b, "        <Credentials>\n          <Domain>%s</Domain>\n          <Username>%s</Username>\n          <Password>%s</Password>\n        </Credentials>\n",
+				xmlEscape(dj.Domain.ValueString()), xmlEscape(dj.Username.ValueString()), xmlEscape(dj.Password.ValueString()))
+			fmt.Fprintf(&b, "        <JoinDomain>%s</JoinDomain>\n", xmlEscape(dj.Domain.ValueString()))
+			if !dj.OU.IsNull() && dj.OU.ValueString() != "" {
+				fmt.Fprintf(&b, "        <MachineObjectOU>%s</MachineObjectOU>\n", xmlEscape(dj.OU.ValueString()))
+			}
+			b.WriteString(`      </Identification>` + "\n")
+			b.WriteString(`    </component>` + "\n")
+		} else if !network.Workgroup.IsNull() && network.Workgroup.ValueString() != "" {
+			b.WriteString(`    <component name="Microsoft-Windows-UnattendedJoin" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">` + "\n")
+			fmt.Fprintf(&b, "      <Identification>\n        <JoinWorkgroup>%s</JoinWorkgroup>\n      </Identification>\n", xmlEscape(network.Workgroup.ValueString()))
+			b.WriteString(`    </component>` + "\n")
+		}
+
+		if !network.DHCP.ValueBool() {
+			b.WriteString(`    <component name="Microsoft-Windows-TCPIP" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">` + "\n")
+			b.WriteString(`      <Interfaces>` + "\n")
+			b.WriteString(`        <Interface wcm:action="add">` + "\n")
+			b.WriteString(`          <Identifier>Ethernet</Identifier>` + "\n")
+			b.WriteString(`          <UnicastIpAddresses>` + "\n")
+			fmt.Fprintf(&b, "            <IpAddress wcm:action=\"add\" wcm:keyValue=\"1\">%s</IpAddress>\n", xmlEscape(network.StaticIP.ValueString()))
+			b.WriteString(`          </UnicastIpAddresses>` + "\n")
+			if !network.Gateway.IsNull() && network.Gateway.ValueString() != "" {
+				fmt.Fprintf(&b, "          <Routes>\n            <Route wcm:action=\"add\">\n              <Identifier>0</Identifier>\n              <Prefix>0.0.0.0/0</Prefix>\n              <NextHopAddress>%s</NextHopAddress>\n            </Route>\n          </Routes>\n", xmlEscape(network.Gateway.ValueString()))
+			}
+			b.WriteString(`        </Interface>` + "\n")
+			b.WriteString(`      </Interfaces>` + "\n")
+			b.WriteString(`    </component>` + "\n")
+		}
+	}
+
+	var syncCommands []string
+	diags = append(diags, data.RunSynchronousCommands.ElementsAs(ctx, &syncCommands, false)...)
+	if len(syncCommands) > 0 {
+		b.WriteString(`    <component name="Microsoft-Windows-Deployment" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">` + "\n")
+		b.WriteString(`      <RunSynchronous>` + "\n")
+		for i, cmd := range syncCommands {
+			fmt.Fprintf(&b, "        <RunSynchronousCommand wcm:action=\"add\">\n          <Order>%d</Order>\n          <Path>%s</Path>\n        </RunSynchronousCommand>\n", i+1, xmlEscape(cmd))
+		}
+		b.WriteString(`      </RunSynchronous>` + "\n")
+		b.WriteString(`    </component>` + "\n")
+	}
+
+	b.WriteString(`  </settings>` + "\n")
+
+	// oobeSystem pass: locale, OOBE toggles, user accounts, first-logon commands.
+	b.WriteString(`  <settings pass="oobeSystem">` + "\n")
+	b.WriteString(`    <component name="Microsoft-Windows-International-Core" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">` + "\n")
+	fmt.Fprintf(&b, "      <UILanguage>%s</UILanguage>\n", xmlEscape(data.Locale.ValueString()))
+	b.WriteString(`    </component>` + "\n")
+
+	b.WriteString(`    <component name="Microsoft-Windows-Shell-Setup" processorArchitecture="amd64" publicKeyToken="31bf3856ad364e35" language="neutral" versionScope="nonSxS">` + "\n")
+
+	if !data.OOBE.IsNull() {
+		var oobe oobeModel
+		diags = append(diags, data.OOBE.As(ctx, &oobe, defaultObjectAsOptions)...)
+		b.WriteString(`      <OOBE>` + "\n")
+		fmt.Fprintf(&b, "        <HideEULAPage>%t</HideEULAPage>\n", oobe.SkipEULA.ValueBool())
+		fmt.Fprintf(&b, "        <HideWirelessSetupInOOBE>%t</HideWirelessSetupInOOBE>\n", oobe.SkipNetwork.ValueBool())
+		fmt.Fprintf(&b, "        <HideOnlineAccountScreens>%t</HideOnlineAccountScreens>\n", oobe.SkipMicrosoftAccount.ValueBool())
+		b.WriteString(`      </OOBE>` + "\n")
+	}
+
+	var accounts []userAccountModel
+	diags = append(diags, data.UserAccounts.ElementsAs(ctx, &accounts, false)...)
+	if len(accounts) > 0 {
+		b.WriteString(`      <UserAccounts>` + "\n")
+		b.WriteString(`        <LocalAccounts>` + "\n")
+		for _, u := range accounts {
+			b.WriteString(`          <LocalAccount wcm:action="add">` + "\n")
+			fmt.Fprintf(&b, "            <Name>%s</Name>\n", xmlEscape(u.Name.ValueString()))
+			fmt.Fprintf(&b, "            <Group>%s</Group>\n", xmlEscape(u.Group.ValueString()))
+			fmt.Fprintf(&b, "            <Password>\n              <Value>%s</Value>\n              <PlainText>true</PlainText>\n            </Password>\n", xmlEscape(u.Password.ValueString()))
+			b.WriteString(`          </LocalAccount>` + "\n")
+		}
+		b.WriteString(`        </LocalAccounts>` + "\n")
+		b.WriteString(`      </UserAccounts>` + "\n")
+
+		for _, u := range accounts {
+			if u.AutoLogon.ValueBool() {
+				b.WriteString(`      <AutoLogon>` + "\n")
+				b.WriteString(`        <Enabled>true</Enabled>` + "\n")
+				fmt.Fprintf(&b, "        <Username>%s</Username>\n", xmlEscape(u.Name.ValueString()))
+				fmt.Fprintf(&b, "        <Password>\n          <Value>%s</Value>\n          <PlainText>true</PlainText>\n        </Password>\n", xmlEscape(u.Password.ValueString()))
+				b.WriteString(`      </AutoLogon>` + "\n")
+				break
+			}
+		}
+	}
+
+	var firstLogonCommands []string
+	diags = append(diags, data.FirstLogonCommands.ElementsAs(ctx, &firstLogonCommands, false)...)
+	if len(firstLogonCommands) > 0 {
+		b.WriteString(`      <FirstLogonCommands>` + "\n")
+		for i, cmd := range firstLogonCommands {
+			fmt.Fprintf(&b, "        <SynchronousCommand wcm:action=\"add\">\n          <Order>%d</Order>\n          <CommandLine>%s</CommandLine>\n        </SynchronousCommand>\n", i+1, xmlEscape(cmd))
+		}
+		b.WriteString(`      </FirstLogonCommands>` + "\n")
+	}
+
+	b.WriteString(`    </component>` + "\n")
+	b.WriteString(`  </settings>` + "\n")
+	b.WriteString(`</unattend>` + "\n")
+
+	return b.String(), diags
+}
+
+func xmlEscape(s string) string {
+	replacer := strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+		`"`, "&quot;",
+		"'", "&apos;",
+	)
+	return replacer.Replace(s)
+}
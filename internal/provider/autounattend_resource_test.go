@@ -0,0 +1,158 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Attribute type maps mirroring the nested model structs' tfsdk tags, used
+// only to build fixtures for renderAutounattendXML below.
+var (
+	diskPartitionAttributeTypes = map[string]attr.Type{
+		"type":    types.StringType,
+		"size_mb": types.Int64Type,
+		"format":  types.StringType,
+		"letter":  types.StringType,
+	}
+	userAccountAttributeTypes = map[string]attr.Type{
+		"name":       types.StringType,
+		"password":   types.StringType,
+		"group":      types.StringType,
+		"auto_logon": types.BoolType,
+	}
+	domainJoinAttributeTypes = map[string]attr.Type{
+		"domain":   types.StringType,
+		"ou":       types.StringType,
+		"username": types.StringType,
+		"password": types.StringType,
+	}
+	networkAttributeTypes = map[string]attr.Type{
+		"dhcp":        types.BoolType,
+		"static_ip":   types.StringType,
+		"subnet_mask": types.StringType,
+		"gateway":     types.StringType,
+		"dns_servers": types.ListType{ElemType: types.StringType},
+		"workgroup":   types.StringType,
+		"domain_join": types.ObjectType{AttrTypes: domainJoinAttributeTypes},
+	}
+	oobeAttributeTypes = map[string]attr.Type{
+		"skip_eula":              types.BoolType,
+		"skip_network":           types.BoolType,
+		"skip_microsoft_account": types.BoolType,
+	}
+)
+
+func TestXMLEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "plain", want: "plain"},
+		{in: `<a & "b" 'c'>`, want: "&lt;a &amp; &quot;b&quot; &apos;c&apos;&gt;"},
+	}
+	for _, tt := range tests {
+		if got := xmlEscape(tt.in); got != tt.want {
+			t.Errorf("xmlEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func newAutounattendData(t *testing.T) *AutounattendXMLResourceModel {
+	t.Helper()
+	ctx := context.Background()
+
+	emptyStringList, diags := types.ListValueFrom(ctx, types.StringType, []string{})
+	if diags.HasError() {
+		t.Fatalf("building empty string list: %v", diags)
+	}
+
+	return &AutounattendXMLResourceModel{
+		WindowsEdition:         types.StringValue("Windows Server 2022 SERVERSTANDARD"),
+		ComputerName:           types.StringValue("HOST-01"),
+		Locale:                 types.StringValue("en-US"),
+		Timezone:               types.StringValue("UTC"),
+		DiskConfiguration:      types.ListNull(types.ObjectType{AttrTypes: diskPartitionAttributeTypes}),
+		UserAccounts:           types.ListNull(types.ObjectType{AttrTypes: userAccountAttributeTypes}),
+		FirstLogonCommands:     emptyStringList,
+		RunSynchronousCommands: emptyStringList,
+		Network:                types.ObjectNull(networkAttributeTypes),
+		OOBE:                   types.ObjectNull(oobeAttributeTypes),
+	}
+}
+
+func TestRenderAutounattendXMLMinimal(t *testing.T) {
+	ctx := context.Background()
+	data := newAutounattendData(t)
+
+	xmlContent, diags := renderAutounattendXML(ctx, data)
+	if diags.HasError() {
+		t.Fatalf("renderAutounattendXML() diagnostics: %v", diags)
+	}
+
+	for _, want := range []string{
+		`<?xml version="1.0" encoding="utf-8"?>`,
+		`<ComputerName>HOST-01</ComputerName>`,
+		`<UILanguage>en-US</UILanguage>`,
+		`</unattend>`,
+	} {
+		if !strings.Contains(xmlContent, want) {
+			t.Errorf("renderAutounattendXML() missing %q in:\n%s", want, xmlContent)
+		}
+	}
+	if strings.Contains(xmlContent, "<DiskConfiguration>") {
+		t.Error("renderAutounattendXML() should omit <DiskConfiguration> when disk_configuration is unset")
+	}
+}
+
+func TestRenderAutounattendXMLEscapesComputerName(t *testing.T) {
+	ctx := context.Background()
+	data := newAutounattendData(t)
+	data.ComputerName = types.StringValue(`HOST<&>"01`)
+
+	xmlContent, diags := renderAutounattendXML(ctx, data)
+	if diags.HasError() {
+		t.Fatalf("renderAutounattendXML() diagnostics: %v", diags)
+	}
+
+	if strings.Contains(xmlContent, "<ComputerName>HOST<&>") {
+		t.Error("renderAutounattendXML() did not escape special characters in computer_name")
+	}
+	if !strings.Contains(xmlContent, "<ComputerName>HOST&lt;&amp;&gt;&quot;01</ComputerName>") {
+		t.Errorf("renderAutounattendXML() computer_name not escaped as expected:\n%s", xmlContent)
+	}
+}
+
+func TestRenderAutounattendXMLWorkgroup(t *testing.T) {
+	ctx := context.Background()
+	data := newAutounattendData(t)
+
+	network, diags := types.ObjectValueFrom(ctx, networkAttributeTypes, networkModel{
+		DHCP:       types.BoolValue(true),
+		Workgroup:  types.StringValue("WORKGROUP"),
+		DomainJoin: types.ObjectNull(domainJoinAttributeTypes),
+		DNSServers: types.ListNull(types.StringType),
+	})
+	if diags.HasError() {
+		t.Fatalf("building network object: %v", diags)
+	}
+	data.Network = network
+
+	xmlContent, diags := renderAutounattendXML(ctx, data)
+	if diags.HasError() {
+		t.Fatalf("renderAutounattendXML() diagnostics: %v", diags)
+	}
+
+	if !strings.Contains(xmlContent, "<JoinWorkgroup>WORKGROUP</JoinWorkgroup>") {
+		t.Errorf("renderAutounattendXML() missing workgroup join:\n%s", xmlContent)
+	}
+	if strings.Contains(xmlContent, "<JoinDomain>") {
+		t.Error("renderAutounattendXML() should not emit <JoinDomain> when only workgroup is set")
+	}
+}
@@ -0,0 +1,344 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/kdomanski/iso9660"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ resource.Resource = &CloudInitISOResource{}
+var _ resource.ResourceWithImportState = &CloudInitISOResource{}
+
+func NewCloudInitISOResource() resource.Resource {
+	return &CloudInitISOResource{}
+}
+
+// CloudInitISOResource builds a NoCloud/CIDATA ISO for cloud-init, the Linux
+// sibling of UnattendedISOResource.
+type CloudInitISOResource struct {
+	providerConfig *unattendProviderConfig
+}
+
+// CloudInitISOResourceModel describes the resource data model.
+type CloudInitISOResourceModel struct {
+	Id            types.String `tfsdk:"id"`
+	FileName      types.String `tfsdk:"file_name"`
+	PathOverride  types.String `tfsdk:"path_override"`
+	UserData      types.String `tfsdk:"user_data"`
+	MetaData      types.String `tfsdk:"meta_data"`
+	NetworkConfig types.String `tfsdk:"network_config"`
+	InstanceID    types.String `tfsdk:"instance_id"`
+	Hostname      types.String `tfsdk:"hostname"`
+	ResultPath    types.String `tfsdk:"result_path"`
+	SHA256        types.String `tfsdk:"sha256"`
+	SizeBytes     types.Int64  `tfsdk:"size_bytes"`
+}
+
+func (r *CloudInitISOResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cloudinit_iso"
+}
+
+func (r *CloudInitISOResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Cloud-init NoCloud ISO Resource. Writes `user-data`, `meta-data`, and an optional `network-config` to an ISO labeled `CIDATA`, as expected by cloud-init's NoCloud datasource.",
+
+		Attributes: map[string]schema.Attribute{
+			"path_override": schema.StringAttribute{
+				MarkdownDescription: "Path to write the local ISO file, defaults to OS temp. Changing this forces recreation, since the old file at the previous path is not moved.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("tmp"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"file_name": schema.StringAttribute{
+				MarkdownDescription: "Name for the created ISO file. Changing this forces recreation, since the old file under the previous name is not renamed.",
+				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"user_data": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Contents of `user-data`. Must start with `#cloud-config` or be a valid MIME multipart document.",
+				Validators: []validator.String{
+					cloudInitUserDataValidator{},
+				},
+			},
+			"meta_data": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Contents of `meta-data`.",
+			},
+			"network_config": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Contents of `network-config`. Omit to let cloud-init fall back to DHCP.",
+			},
+			"instance_id": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Instance ID written into `meta-data` if not already present there. Defaults to `file_name`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"hostname": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Hostname hint for cloud-init. Purely informational; not injected into `meta-data` automatically.",
+			},
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Cloud-init ISO identifier",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"result_path": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Resultant File Path",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "SHA-256 of the generated ISO file. Recomputed on every `Read`; a mismatch with the value on disk means the file was tampered with out of band.",
+			},
+			"size_bytes": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Size of the generated ISO file, in bytes.",
+			},
+		},
+	}
+}
+
+func (r *CloudInitISOResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	config, ok := req.ProviderData.(*unattendProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *unattendProviderConfig, got: %T.", req.ProviderData))
+		return
+	}
+	r.providerConfig = config
+}
+
+func (r *CloudInitISOResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var data CloudInitISOResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if data.InstanceID.IsUnknown() || data.InstanceID.IsNull() {
+		data.InstanceID = types.StringValue(data.FileName.ValueString())
+	}
+
+	data.Id = types.StringValue(data.FileName.ValueString())
+	data.ResultPath = types.StringValue(r.resolveResultPath(&data))
+
+	tflog.Trace(ctx, "created a cloudinit_iso resource")
+
+	if !r.writeISO(&data, &resp.Diagnostics) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// resolveResultPath computes the on-disk path the ISO should be written to.
+// See unattendProviderConfig.resolveOutputPath for the path_override rules.
+func (r *CloudInitISOResource) resolveResultPath(data *CloudInitISOResourceModel) string {
+	return r.providerConfig.resolveOutputPath(data.PathOverride.ValueString(), data.FileName.ValueString())
+}
+
+// withInstanceID returns metaData with an "instance-id:" key prepended when
+// one isn't already present at the top level, so instance_id is always the
+// value cloud-init actually keys its "has this instance already run" check
+// on.
+func withInstanceID(metaData, instanceID string) string {
+	for _, line := range strings.Split(metaData, "\n") {
+		if strings.HasPrefix(line, "instance-id:") {
+			return metaData
+		}
+	}
+	return fmt.Sprintf("instance-id: %s\n%s", instanceID, metaData)
+}
+
+// writeISO renders the CIDATA ISO and writes it to data.ResultPath,
+// populating data.SHA256 / data.SizeBytes.
+func (r *CloudInitISOResource) writeISO(data *CloudInitISOResourceModel, diags *diag.Diagnostics) bool {
+	isoWriter, err := iso9660.NewWriter()
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to start ISO Writer, got error: %s", err))
+		return false
+	}
+	defer func(isoWriter *iso9660.ImageWriter) {
+		if err := isoWriter.Cleanup(); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Error in ISO Writer, got error: %s", err))
+		}
+	}(isoWriter)
+
+	if err := isoWriter.AddFile(strings.NewReader(data.UserData.ValueString()), "user-data"); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Error adding user-data to ISO, got error: %s", err))
+		return false
+	}
+	metaData := withInstanceID(data.MetaData.ValueString(), data.InstanceID.ValueString())
+	if err := isoWriter.AddFile(strings.NewReader(metaData), "meta-data"); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Error adding meta-data to ISO, got error: %s", err))
+		return false
+	}
+	if !data.NetworkConfig.IsNull() && data.NetworkConfig.ValueString() != "" {
+		if err := isoWriter.AddFile(strings.NewReader(data.NetworkConfig.ValueString()), "network-config"); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Error adding network-config to ISO, got error: %s", err))
+			return false
+		}
+	}
+
+	var b bytes.Buffer
+	// The volume label must be exactly "CIDATA" for cloud-init's NoCloud
+	// datasource to recognize the disk, so iso_volume_prefix doesn't apply here.
+	if err := isoWriter.WriteTo(&b, "CIDATA"); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Error writing to ISO, got error: %s", err))
+		return false
+	}
+
+	resultPath := data.ResultPath.ValueString()
+	if err := os.WriteFile(resultPath, b.Bytes(), 0o644); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Error writing ISO to %q, got error: %s", resultPath, err))
+		return false
+	}
+
+	sum, err := r.providerConfig.hashContent(b.Bytes())
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Error hashing ISO, got error: %s", err))
+		return false
+	}
+	data.SHA256 = types.StringValue(sum)
+	data.SizeBytes = types.Int64Value(int64(b.Len()))
+
+	return true
+}
+
+func (r *CloudInitISOResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var data CloudInitISOResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	result, err := r.providerConfig.readBackISO(data.ResultPath.ValueString(), data.SHA256.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+		return
+	}
+	if result.Missing {
+		tflog.Trace(ctx, "cloud-init ISO file is gone, removing from state", map[string]interface{}{"result_path": data.ResultPath.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if result.Drifted {
+		tflog.Trace(ctx, "cloud-init ISO file hash drifted, removing from state", map[string]interface{}{"result_path": data.ResultPath.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.SHA256 = types.StringValue(result.SHA256)
+	data.SizeBytes = types.Int64Value(result.Size)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudInitISOResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var data CloudInitISOResourceModel
+
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !r.writeISO(&data, &resp.Diagnostics) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+func (r *CloudInitISOResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var data CloudInitISOResourceModel
+
+	resp.Diagnostics.Append(req.State.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := removeISOFile(data.ResultPath.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+		return
+	}
+}
+
+func (r *CloudInitISOResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+// cloudInitUserDataValidator enforces that user_data looks like something
+// cloud-init's NoCloud datasource will actually parse: either the
+// #cloud-config header or a MIME multipart document (used to combine
+// multiple cloud-init config types in one file).
+type cloudInitUserDataValidator struct{}
+
+func (v cloudInitUserDataValidator) Description(ctx context.Context) string {
+	return "user_data must start with \"#cloud-config\" or be a valid MIME multipart document"
+}
+
+func (v cloudInitUserDataValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v cloudInitUserDataValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	value := req.ConfigValue.ValueString()
+	if strings.HasPrefix(value, "#cloud-config") {
+		return
+	}
+
+	if _, params, err := mime.ParseMediaType(strings.TrimSpace(strings.SplitN(value, "\n", 2)[0])); err == nil {
+		if _, ok := params["boundary"]; ok {
+			return
+		}
+	}
+	if strings.HasPrefix(value, "Content-Type: multipart/") || strings.HasPrefix(value, "MIME-Version:") {
+		return
+	}
+
+	resp.Diagnostics.AddAttributeError(req.Path, "Invalid user_data",
+		"user_data must start with \"#cloud-config\" or be a valid MIME multipart document (e.g. begin with \"Content-Type: multipart/mixed; boundary=...\").")
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCloudInitUserDataValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "cloud-config header", value: "#cloud-config\npackages: [curl]"},
+		{name: "mime multipart with boundary", value: "Content-Type: multipart/mixed; boundary=\"MIMEBOUNDARY\"\n\n--MIMEBOUNDARY--"},
+		{name: "mime-version header", value: "MIME-Version: 1.0\nContent-Type: multipart/mixed; boundary=\"x\""},
+		{name: "plain multipart prefix without parseable media type", value: "Content-Type: multipart/mixed\n\nbody"},
+		{name: "empty string", value: "", wantErr: true},
+		{name: "plain shell script", value: "#!/bin/bash\necho hi", wantErr: true},
+		{name: "yaml without cloud-config header", value: "packages:\n  - curl", wantErr: true},
+	}
+
+	v := cloudInitUserDataValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validator.StringRequest{
+				Path:        path.Root("user_data"),
+				ConfigValue: types.StringValue(tt.value),
+			}
+			var resp validator.StringResponse
+
+			v.ValidateString(context.Background(), req, &resp)
+
+			if tt.wantErr && !resp.Diagnostics.HasError() {
+				t.Errorf("ValidateString(%q): expected an error, got none", tt.value)
+			}
+			if !tt.wantErr && resp.Diagnostics.HasError() {
+				t.Errorf("ValidateString(%q): unexpected error(s): %v", tt.value, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestCloudInitUserDataValidatorSkipsNullAndUnknown(t *testing.T) {
+	v := cloudInitUserDataValidator{}
+
+	for _, value := range []types.String{types.StringNull(), types.StringUnknown()} {
+		req := validator.StringRequest{Path: path.Root("user_data"), ConfigValue: value}
+		var resp validator.StringResponse
+
+		v.ValidateString(context.Background(), req, &resp)
+
+		if resp.Diagnostics.HasError() {
+			t.Errorf("ValidateString(%v): expected null/unknown to be skipped, got error(s): %v", value, resp.Diagnostics)
+		}
+	}
+}
+
+func TestWithInstanceID(t *testing.T) {
+	tests := []struct {
+		name       string
+		metaData   string
+		instanceID string
+		want       string
+	}{
+		{
+			name:       "no existing instance-id",
+			metaData:   "local-hostname: web-01",
+			instanceID: "web-01",
+			want:       "instance-id: web-01\nlocal-hostname: web-01",
+		},
+		{
+			name:       "existing instance-id is left untouched",
+			metaData:   "instance-id: already-set\nlocal-hostname: web-01",
+			instanceID: "web-01",
+			want:       "instance-id: already-set\nlocal-hostname: web-01",
+		},
+		{
+			name:       "empty meta-data",
+			metaData:   "",
+			instanceID: "web-01",
+			want:       "instance-id: web-01\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := withInstanceID(tt.metaData, tt.instanceID); got != tt.want {
+				t.Errorf("withInstanceID(%q, %q) = %q, want %q", tt.metaData, tt.instanceID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveResultPathDelegatesToProviderConfig(t *testing.T) {
+	r := &CloudInitISOResource{providerConfig: &unattendProviderConfig{defaultOutputDir: "/data/cloudinit"}}
+	data := &CloudInitISOResourceModel{
+		PathOverride: types.StringValue("tmp"),
+		FileName:     types.StringValue("seed.iso"),
+	}
+
+	got := r.resolveResultPath(data)
+	want := r.providerConfig.resolveOutputPath("tmp", "seed.iso")
+	if got != want {
+		t.Errorf("resolveResultPath() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// unattendProviderConfig is the resolved provider-level policy, handed to
+// every resource and data source via Configure's req.ProviderData. Defaults
+// are applied once here so resources never need to re-derive them.
+type unattendProviderConfig struct {
+	// defaultOutputDir is used whenever a resource's path_override is left
+	// at its "tmp" sentinel default.
+	defaultOutputDir string
+	// hashAlgorithm is one of "sha256", "sha512", or "none".
+	hashAlgorithm string
+	// templateVars is merged into every rendered XML document via
+	// text/template before it's written to an ISO.
+	templateVars map[string]string
+	// isoVolumePrefix is prepended to every resource's ISO volume label.
+	isoVolumePrefix string
+}
+
+const (
+	hashAlgorithmSHA256 = "sha256"
+	hashAlgorithmSHA512 = "sha512"
+	hashAlgorithmNone   = "none"
+)
+
+// hashContent hashes content with the configured algorithm, returning ""
+// (not an error) when hashing is turned off via hash_algorithm = "none".
+func (c *unattendProviderConfig) hashContent(content []byte) (string, error) {
+	var algorithm string
+	if c != nil {
+		algorithm = c.hashAlgorithm
+	}
+	switch algorithm {
+	case "", hashAlgorithmSHA256:
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:]), nil
+	case hashAlgorithmSHA512:
+		sum := sha512.Sum512(content)
+		return hex.EncodeToString(sum[:]), nil
+	case hashAlgorithmNone:
+		return "", nil
+	default:
+		return "", fmt.Errorf("unsupported hash_algorithm %q", c.hashAlgorithm)
+	}
+}
+
+// volumeLabel prepends iso_volume_prefix, if any, to a resource's default
+// volume label.
+func (c *unattendProviderConfig) volumeLabel(label string) string {
+	if c == nil || c.isoVolumePrefix == "" {
+		return label
+	}
+	return c.isoVolumePrefix + label
+}
+
+// resolveOutputPath computes the on-disk path an ISO resource should write
+// to. pathOverride defaults to the sentinel "tmp", meaning "use the
+// provider's default_output_dir, or the OS temp directory if that's also
+// unset"; any other value is treated as a directory the file is written
+// into under fileName. Shared by UnattendedISOResource and
+// CloudInitISOResource.
+func (c *unattendProviderConfig) resolveOutputPath(pathOverride, fileName string) string {
+	if pathOverride != "tmp" {
+		return filepath.Join(pathOverride, fileName)
+	}
+	dir := os.TempDir()
+	if c != nil && c.defaultOutputDir != "" {
+		dir = c.defaultOutputDir
+	}
+	return filepath.Join(dir, fileName)
+}
+
+// isoReadResult is the outcome of readBackISO: exactly one of Missing,
+// Drifted, or a fresh SHA256/Size pair is populated.
+type isoReadResult struct {
+	Missing bool
+	Drifted bool
+	SHA256  string
+	Size    int64
+}
+
+// readBackISO re-reads a previously written ISO file and compares its hash
+// against the value recorded in state, the shared core of both ISO
+// resources' Read: a file that's gone, or whose contents drifted out of
+// band, is reported so the caller can drop it from state; otherwise the
+// freshly computed hash and size are returned.
+func (c *unattendProviderConfig) readBackISO(resultPath, priorSHA256 string) (isoReadResult, error) {
+	info, err := os.Stat(resultPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return isoReadResult{Missing: true}, nil
+		}
+		return isoReadResult{}, fmt.Errorf("unable to stat %q: %w", resultPath, err)
+	}
+
+	contents, err := os.ReadFile(resultPath)
+	if err != nil {
+		return isoReadResult{}, fmt.Errorf("unable to read %q: %w", resultPath, err)
+	}
+
+	actualSHA256, err := c.hashContent(contents)
+	if err != nil {
+		return isoReadResult{}, fmt.Errorf("unable to hash %q: %w", resultPath, err)
+	}
+
+	if actualSHA256 != "" && priorSHA256 != "" && priorSHA256 != actualSHA256 {
+		return isoReadResult{Drifted: true}, nil
+	}
+
+	return isoReadResult{SHA256: actualSHA256, Size: info.Size()}, nil
+}
+
+// removeISOFile deletes a generated ISO file, treating it already being
+// gone as success. Shared by both ISO resources' Delete.
+func removeISOFile(resultPath string) error {
+	if err := os.Remove(resultPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("unable to delete %q: %w", resultPath, err)
+	}
+	return nil
+}
+
+// renderTemplate runs content through text/template with provider-level
+// template_vars merged in, so one XML document can be shared across many
+// hostnames without pre-processing with Terraform's templatefile().
+func (c *unattendProviderConfig) renderTemplate(name, content string) (string, error) {
+	if c == nil || len(c.templateVars) == 0 {
+		return content, nil
+	}
+
+	tmpl, err := template.New(name).Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, c.templateVars); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,191 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHashContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		wantLen   int
+		wantErr   bool
+	}{
+		{name: "default is sha256", algorithm: "", wantLen: 64},
+		{name: "explicit sha256", algorithm: hashAlgorithmSHA256, wantLen: 64},
+		{name: "sha512", algorithm: hashAlgorithmSHA512, wantLen: 128},
+		{name: "none", algorithm: hashAlgorithmNone, wantLen: 0},
+		{name: "unsupported", algorithm: "md5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &unattendProviderConfig{hashAlgorithm: tt.algorithm}
+			got, err := config.hashContent([]byte("hello"))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("hashContent() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("hashContent() unexpected error: %v", err)
+			}
+			if len(got) != tt.wantLen {
+				t.Errorf("hashContent() = %q, want length %d", got, tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestHashContentNilReceiver(t *testing.T) {
+	var config *unattendProviderConfig
+	got, err := config.hashContent([]byte("hello"))
+	if err != nil {
+		t.Fatalf("hashContent() on nil config returned error: %v", err)
+	}
+	if len(got) != 64 {
+		t.Errorf("hashContent() on nil config = %q, want sha256 default", got)
+	}
+}
+
+func TestVolumeLabel(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *unattendProviderConfig
+		label  string
+		want   string
+	}{
+		{name: "nil config", config: nil, label: "unattend", want: "unattend"},
+		{name: "no prefix", config: &unattendProviderConfig{}, label: "unattend", want: "unattend"},
+		{name: "with prefix", config: &unattendProviderConfig{isoVolumePrefix: "ACME-"}, label: "unattend", want: "ACME-unattend"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.volumeLabel(tt.label); got != tt.want {
+				t.Errorf("volumeLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	config := &unattendProviderConfig{templateVars: map[string]string{"Hostname": "web-01"}}
+
+	got, err := config.renderTemplate("test", "name={{.Hostname}}")
+	if err != nil {
+		t.Fatalf("renderTemplate() unexpected error: %v", err)
+	}
+	if want := "name=web-01"; got != want {
+		t.Errorf("renderTemplate() = %q, want %q", got, want)
+	}
+
+	if _, err := config.renderTemplate("test", "name={{.Nope"); err == nil {
+		t.Error("renderTemplate() with malformed template: expected error, got nil")
+	}
+}
+
+func TestRenderTemplateNoVars(t *testing.T) {
+	var config *unattendProviderConfig
+	got, err := config.renderTemplate("test", "name={{.Hostname}}")
+	if err != nil {
+		t.Fatalf("renderTemplate() unexpected error: %v", err)
+	}
+	if want := "name={{.Hostname}}"; got != want {
+		t.Errorf("renderTemplate() with no template_vars should pass content through unchanged, got %q", got)
+	}
+}
+
+func TestResolveOutputPath(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       *unattendProviderConfig
+		pathOverride string
+		fileName     string
+		want         string
+	}{
+		{
+			name:         "explicit directory",
+			config:       &unattendProviderConfig{},
+			pathOverride: "/srv/isos",
+			fileName:     "unattend.iso",
+			want:         filepath.Join("/srv/isos", "unattend.iso"),
+		},
+		{
+			name:         "tmp sentinel uses provider default_output_dir",
+			config:       &unattendProviderConfig{defaultOutputDir: "/data/out"},
+			pathOverride: "tmp",
+			fileName:     "unattend.iso",
+			want:         filepath.Join("/data/out", "unattend.iso"),
+		},
+		{
+			name:         "tmp sentinel with no provider config falls back to OS temp",
+			config:       nil,
+			pathOverride: "tmp",
+			fileName:     "unattend.iso",
+			want:         filepath.Join(os.TempDir(), "unattend.iso"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.resolveOutputPath(tt.pathOverride, tt.fileName); got != tt.want {
+				t.Errorf("resolveOutputPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadBackISO(t *testing.T) {
+	config := &unattendProviderConfig{}
+	dir := t.TempDir()
+	resultPath := filepath.Join(dir, "unattend.iso")
+
+	if _, err := config.readBackISO(resultPath, ""); err != nil {
+		t.Fatalf("readBackISO() on missing file returned error: %v", err)
+	}
+	if result, _ := config.readBackISO(resultPath, ""); !result.Missing {
+		t.Errorf("readBackISO() on missing file: Missing = false, want true")
+	}
+
+	if err := os.WriteFile(resultPath, []byte("contents"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	result, err := config.readBackISO(resultPath, "")
+	if err != nil {
+		t.Fatalf("readBackISO() unexpected error: %v", err)
+	}
+	if result.Missing || result.Drifted || result.SHA256 == "" {
+		t.Errorf("readBackISO() = %+v, want a fresh, non-drifted result", result)
+	}
+
+	if result, err := config.readBackISO(resultPath, "not-the-real-hash"); err != nil || !result.Drifted {
+		t.Errorf("readBackISO() with mismatched prior hash = %+v, %v, want Drifted = true", result, err)
+	}
+}
+
+func TestRemoveISOFile(t *testing.T) {
+	dir := t.TempDir()
+	resultPath := filepath.Join(dir, "unattend.iso")
+
+	if err := removeISOFile(resultPath); err != nil {
+		t.Errorf("removeISOFile() on missing file returned error: %v", err)
+	}
+
+	if err := os.WriteFile(resultPath, []byte("contents"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := removeISOFile(resultPath); err != nil {
+		t.Errorf("removeISOFile() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(resultPath); !os.IsNotExist(err) {
+		t.Errorf("removeISOFile() did not remove %q", resultPath)
+	}
+}
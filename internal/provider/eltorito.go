@@ -0,0 +1,347 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// github.com/kdomanski/iso9660's writer has no concept of El Torito: it only
+// knows how to lay out a plain ISO9660 data disc. To make a disc bootable we
+// let it write that plain disc as normal, then splice an El Torito Boot
+// Record Volume Descriptor and boot catalog into the result ourselves,
+// patching every extent pointer that the insertion shifts. The splicing only
+// relies on offsets ECMA-119 fixes for every compliant writer (the Primary
+// Volume Descriptor layout, path tables, and directory records), not on
+// anything specific to kdomanski/iso9660's internals.
+
+const isoSectorSize = 2048
+
+// elToritoBootImage is one El Torito boot entry: a BIOS or UEFI payload to
+// embed in the boot catalog.
+type elToritoBootImage struct {
+	// image is the boot payload, already validated as a multiple of
+	// isoSectorSize bytes.
+	image []byte
+	// noEmulation selects the El Torito "no emulation" media type (boot the
+	// image as-is) over hard-disk emulation.
+	noEmulation bool
+	// loadSize is the number of 512-byte virtual sectors to load at boot.
+	loadSize uint16
+}
+
+const (
+	elToritoPlatformBIOS byte = 0x00
+	elToritoPlatformEFI  byte = 0xEF
+)
+
+// spliceElTorito inserts a Boot Record Volume Descriptor and boot catalog
+// into a complete ISO9660 image produced by iso9660.ImageWriter.WriteTo,
+// making it El Torito bootable. bios is required; efi is optional and adds a
+// second, UEFI-platform section entry alongside it.
+func spliceElTorito(base []byte, bios, efi *elToritoBootImage) ([]byte, error) {
+	if bios == nil {
+		return nil, fmt.Errorf("spliceElTorito requires a BIOS boot image")
+	}
+	if len(base)%isoSectorSize != 0 {
+		return nil, fmt.Errorf("ISO image is %d bytes, not a multiple of the %d-byte sector size", len(base), isoSectorSize)
+	}
+	totalSectors := uint32(len(base) / isoSectorSize)
+
+	pvdOffset := 16 * isoSectorSize
+	if pvdOffset+isoSectorSize > len(base) || base[pvdOffset] != 1 || string(base[pvdOffset+1:pvdOffset+6]) != "CD001" {
+		return nil, fmt.Errorf("sector 16 is not a Primary Volume Descriptor")
+	}
+
+	insertionSector, err := findVolumeDescriptorSetTerminator(base)
+	if err != nil {
+		return nil, err
+	}
+
+	sites, err := collectExtentPatchSites(base, pvdOffset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lay out the appended boot catalog and boot images after the last
+	// sector of the existing image, accounting for the one sector we're
+	// about to insert into the volume descriptor set.
+	catalogLBA := totalSectors + 1
+	nextLBA := catalogLBA + 1
+
+	entries := []bootCatalogEntry{{platform: elToritoPlatformBIOS, image: bios, lba: nextLBA}}
+	nextLBA += uint32(len(bios.image) / isoSectorSize)
+	if efi != nil {
+		entries = append(entries, bootCatalogEntry{platform: elToritoPlatformEFI, image: efi, lba: nextLBA})
+		nextLBA += uint32(len(efi.image) / isoSectorSize)
+	}
+	newTotalSectors := nextLBA
+
+	var appended bytes.Buffer
+	appended.Write(buildBootCatalog(entries))
+	for _, e := range entries {
+		appended.Write(e.image.image)
+	}
+
+	var out bytes.Buffer
+	out.Write(base[:insertionSector*isoSectorSize])
+	out.Write(buildBootRecordVolumeDescriptor(catalogLBA))
+	out.Write(base[insertionSector*isoSectorSize:])
+	out.Write(appended.Bytes())
+	result := out.Bytes()
+
+	for _, site := range sites {
+		newOffset := site.offset
+		if site.offset >= insertionSector*isoSectorSize {
+			newOffset += isoSectorSize
+		}
+		value := site.format.read(base[site.offset:])
+		if value >= uint32(insertionSector) {
+			value++
+		}
+		site.format.write(result[newOffset:], value)
+	}
+
+	spaceSizeOffset := pvdOffset + 80 // always before the insertion point
+	format733.write(result[spaceSizeOffset:], newTotalSectors)
+
+	return result, nil
+}
+
+// findVolumeDescriptorSetTerminator returns the sector number of the first
+// Volume Descriptor Set Terminator (type 255) at or after sector 17. The El
+// Torito Boot Record Volume Descriptor is inserted in its place, pushing the
+// terminator (and everything after it) one sector later.
+func findVolumeDescriptorSetTerminator(base []byte) (int, error) {
+	const terminatorType = 255
+	for sector := 17; sector*isoSectorSize < len(base); sector++ {
+		if base[sector*isoSectorSize] == terminatorType {
+			return sector, nil
+		}
+	}
+	return 0, fmt.Errorf("could not find the Volume Descriptor Set Terminator")
+}
+
+// extentFormat describes how an LBA-valued field is encoded on disk, per
+// ECMA-119 7.2/7.3.
+type extentFormat struct {
+	width int
+	read  func([]byte) uint32
+	write func([]byte, uint32)
+}
+
+var (
+	format733 = extentFormat{8, func(b []byte) uint32 { return binary.LittleEndian.Uint32(b[0:4]) }, func(b []byte, v uint32) {
+		binary.LittleEndian.PutUint32(b[0:4], v)
+		binary.BigEndian.PutUint32(b[4:8], v)
+	}}
+	format731 = extentFormat{4, func(b []byte) uint32 { return binary.LittleEndian.Uint32(b[0:4]) }, func(b []byte, v uint32) {
+		binary.LittleEndian.PutUint32(b[0:4], v)
+	}}
+	format732 = extentFormat{4, func(b []byte) uint32 { return binary.BigEndian.Uint32(b[0:4]) }, func(b []byte, v uint32) {
+		binary.BigEndian.PutUint32(b[0:4], v)
+	}}
+)
+
+// extentPatchSite is an LBA-valued field somewhere in the image whose value
+// must be incremented by one once the Boot Record Volume Descriptor pushes
+// everything from the insertion sector onward one sector later.
+type extentPatchSite struct {
+	offset int // absolute byte offset of the field, in the original image
+	format extentFormat
+}
+
+// collectExtentPatchSites walks every structure ECMA-119 defines as
+// containing an extent LBA (the PVD's root directory record and path table
+// pointers, both path tables, and every directory record reachable from the
+// root) and records where each one lives.
+func collectExtentPatchSites(base []byte, pvdOffset int) ([]extentPatchSite, error) {
+	var sites []extentPatchSite
+	sites = append(sites, extentPatchSite{pvdOffset + 158, format733}) // root directory record, embedded in the PVD
+
+	ptlLoc := format731.read(base[pvdOffset+140:])
+	ptlLocOpt := format731.read(base[pvdOffset+144:])
+	ptmLoc := format732.read(base[pvdOffset+148:])
+	ptmLocOpt := format732.read(base[pvdOffset+152:])
+	ptSize := format733.read(base[pvdOffset+132:])
+
+	if ptlLoc != 0 {
+		sites = append(sites, extentPatchSite{pvdOffset + 140, format731})
+		if err := collectPathTablePatchSites(base, ptlLoc, ptSize, format731, &sites); err != nil {
+			return nil, err
+		}
+	}
+	if ptlLocOpt != 0 {
+		sites = append(sites, extentPatchSite{pvdOffset + 144, format731})
+	}
+	if ptmLoc != 0 {
+		sites = append(sites, extentPatchSite{pvdOffset + 148, format732})
+		if err := collectPathTablePatchSites(base, ptmLoc, ptSize, format732, &sites); err != nil {
+			return nil, err
+		}
+	}
+	if ptmLocOpt != 0 {
+		sites = append(sites, extentPatchSite{pvdOffset + 152, format732})
+	}
+
+	rootLBA := format733.read(base[pvdOffset+158:])
+	rootLen := format733.read(base[pvdOffset+166:])
+	if err := collectDirectoryPatchSites(base, rootLBA, rootLen, &sites); err != nil {
+		return nil, err
+	}
+
+	return sites, nil
+}
+
+// collectPathTablePatchSites records the extent field of every entry in one
+// path table (ECMA-119 9.4). Path table entries aren't sector-aligned, only
+// padded to an even length.
+func collectPathTablePatchSites(base []byte, lba, size uint32, format extentFormat, sites *[]extentPatchSite) error {
+	start := int64(lba) * isoSectorSize
+	end := start + int64(size)
+	if end > int64(len(base)) {
+		return fmt.Errorf("path table at sector %d overruns the image", lba)
+	}
+	data := base[start:end]
+
+	pos := 0
+	for pos+8 <= len(data) {
+		lenDI := int(data[pos])
+		recLen := 8 + lenDI
+		if lenDI%2 == 1 {
+			recLen++
+		}
+		if pos+recLen > len(data) {
+			break
+		}
+		*sites = append(*sites, extentPatchSite{int(start) + pos + 2, format})
+		pos += recLen
+	}
+	return nil
+}
+
+// collectDirectoryPatchSites records the extent field of every directory
+// record (ECMA-119 9.1) in the directory at lba/len, recursing into
+// subdirectories. "." and ".." are included, since they reference extents
+// too.
+func collectDirectoryPatchSites(base []byte, lba, length uint32, sites *[]extentPatchSite) error {
+	start := int64(lba) * isoSectorSize
+	end := start + int64(length)
+	if end > int64(len(base)) {
+		return fmt.Errorf("directory at sector %d overruns the image", lba)
+	}
+	content := base[start:end]
+
+	pos := 0
+	for pos < len(content) {
+		sectorOffset := pos % isoSectorSize
+		lenDR := int(content[pos])
+		if lenDR == 0 || sectorOffset+lenDR > isoSectorSize {
+			// Directory records never cross a sector boundary; a zero
+			// length (or one that would) means this is padding to the
+			// next sector.
+			pos += isoSectorSize - sectorOffset
+			continue
+		}
+
+		record := content[pos : pos+lenDR]
+		*sites = append(*sites, extentPatchSite{int(start) + pos + 2, format733})
+
+		flags := record[25]
+		fileIDLen := int(record[32])
+		isDir := flags&0x02 != 0
+		isDotEntry := fileIDLen == 1 && (record[33] == 0x00 || record[33] == 0x01)
+		if isDir && !isDotEntry {
+			childLBA := format733.read(record[2:])
+			childLen := format733.read(record[10:])
+			if err := collectDirectoryPatchSites(base, childLBA, childLen, sites); err != nil {
+				return err
+			}
+		}
+
+		pos += lenDR
+	}
+	return nil
+}
+
+// bootCatalogEntry pairs a boot image with the platform ID and LBA it will
+// be written at, for buildBootCatalog.
+type bootCatalogEntry struct {
+	platform byte
+	image    *elToritoBootImage
+	lba      uint32
+}
+
+// buildBootCatalog lays out the El Torito boot catalog (one 2048-byte
+// sector): a Validation Entry plus an Initial/Default Entry for entries[0],
+// followed by a Section Header + Section Entry pair for every additional
+// platform. See the El Torito specification section 2.
+func buildBootCatalog(entries []bootCatalogEntry) []byte {
+	catalog := make([]byte, isoSectorSize)
+	offset := 0
+
+	copy(catalog[offset:], buildValidationEntry(entries[0].platform))
+	offset += 32
+	copy(catalog[offset:], buildBootEntry(entries[0].image, entries[0].lba))
+	offset += 32
+
+	for _, e := range entries[1:] {
+		copy(catalog[offset:], buildSectionHeaderEntry(e.platform))
+		offset += 32
+		copy(catalog[offset:], buildBootEntry(e.image, e.lba))
+		offset += 32
+	}
+
+	return catalog
+}
+
+func buildValidationEntry(platform byte) []byte {
+	e := make([]byte, 32)
+	e[0] = 0x01 // header ID
+	e[1] = platform
+	e[30] = 0x55
+	e[31] = 0xAA
+
+	var sum uint16
+	for i := 0; i < 32; i += 2 {
+		sum += binary.LittleEndian.Uint16(e[i : i+2])
+	}
+	binary.LittleEndian.PutUint16(e[28:30], -sum)
+	return e
+}
+
+func buildBootEntry(image *elToritoBootImage, lba uint32) []byte {
+	e := make([]byte, 32)
+	e[0] = 0x88 // bootable
+	if image.noEmulation {
+		e[1] = 0x00
+	} else {
+		e[1] = 0x04 // hard disk emulation
+	}
+	binary.LittleEndian.PutUint16(e[6:8], image.loadSize)
+	binary.LittleEndian.PutUint32(e[8:12], lba)
+	return e
+}
+
+func buildSectionHeaderEntry(platform byte) []byte {
+	h := make([]byte, 32)
+	h[0] = 0x91 // final header, one entry follows
+	h[1] = platform
+	binary.LittleEndian.PutUint16(h[2:4], 1)
+	return h
+}
+
+// buildBootRecordVolumeDescriptor builds the Boot Record Volume Descriptor
+// (ECMA-119 8.2) that points El Torito-aware firmware at the boot catalog.
+func buildBootRecordVolumeDescriptor(catalogLBA uint32) []byte {
+	vd := make([]byte, isoSectorSize)
+	vd[0] = 0x00 // Boot Record
+	copy(vd[1:6], "CD001")
+	vd[6] = 0x01
+	copy(vd[7:39], "EL TORITO SPECIFICATION")
+	binary.LittleEndian.PutUint32(vd[71:75], catalogLBA)
+	return vd
+}
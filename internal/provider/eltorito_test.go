@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestBuildValidationEntryChecksum(t *testing.T) {
+	entry := buildValidationEntry(elToritoPlatformBIOS)
+
+	var sum uint16
+	for i := 0; i < 32; i += 2 {
+		sum += binary.LittleEndian.Uint16(entry[i : i+2])
+	}
+	if sum != 0 {
+		t.Errorf("validation entry checksum sums to %d, want 0", sum)
+	}
+	if entry[30] != 0x55 || entry[31] != 0xAA {
+		t.Errorf("validation entry key bytes = %#x %#x, want 0x55 0xAA", entry[30], entry[31])
+	}
+	if entry[1] != elToritoPlatformBIOS {
+		t.Errorf("validation entry platform = %#x, want %#x", entry[1], elToritoPlatformBIOS)
+	}
+}
+
+func TestBuildBootEntry(t *testing.T) {
+	img := &elToritoBootImage{image: make([]byte, 4096), noEmulation: true, loadSize: 4}
+	entry := buildBootEntry(img, 123)
+
+	if entry[0] != 0x88 {
+		t.Errorf("boot entry indicator = %#x, want 0x88 (bootable)", entry[0])
+	}
+	if entry[1] != 0x00 {
+		t.Errorf("no-emulation boot entry media type = %#x, want 0x00", entry[1])
+	}
+	if got := binary.LittleEndian.Uint16(entry[6:8]); got != 4 {
+		t.Errorf("boot entry sector count = %d, want 4", got)
+	}
+	if got := binary.LittleEndian.Uint32(entry[8:12]); got != 123 {
+		t.Errorf("boot entry load LBA = %d, want 123", got)
+	}
+
+	hdImg := &elToritoBootImage{image: make([]byte, 2048), noEmulation: false}
+	if hd := buildBootEntry(hdImg, 0); hd[1] != 0x04 {
+		t.Errorf("hard-disk emulation boot entry media type = %#x, want 0x04", hd[1])
+	}
+}
+
+// buildMinimalISO builds the smallest image spliceElTorito can operate on: a
+// Primary Volume Descriptor and Terminator at sectors 16/17, with an empty
+// root directory (just "." and "..") at sector 18.
+func buildMinimalISO(totalSectors int) []byte {
+	base := make([]byte, totalSectors*isoSectorSize)
+
+	pvd := base[16*isoSectorSize:]
+	pvd[0] = 1
+	copy(pvd[1:6], "CD001")
+	base[17*isoSectorSize] = 255
+	copy(base[17*isoSectorSize+1:], "CD001")
+
+	root := pvd[156:190]
+	root[0] = 34
+	format733.write(root[2:], 18)
+	format733.write(root[10:], isoSectorSize)
+	root[25] = 0x02 // directory
+	root[32] = 1
+	root[33] = 0 // "."
+
+	content := base[18*isoSectorSize:]
+	dot := content[0:34]
+	dot[0] = 34
+	format733.write(dot[2:], 18)
+	dot[25] = 0x02
+	dot[32] = 1
+	dot[33] = 0
+	dotdot := content[34:68]
+	dotdot[0] = 34
+	format733.write(dotdot[2:], 18)
+	dotdot[25] = 0x02
+	dotdot[32] = 1
+	dotdot[33] = 1
+
+	return base
+}
+
+func TestSpliceElToritoInsertsBootRecordAndShiftsExtents(t *testing.T) {
+	const totalSectors = 20
+	base := buildMinimalISO(totalSectors)
+	bios := &elToritoBootImage{image: make([]byte, isoSectorSize), noEmulation: true, loadSize: 4}
+
+	out, err := spliceElTorito(base, bios, nil)
+	if err != nil {
+		t.Fatalf("spliceElTorito() error: %v", err)
+	}
+
+	wantLen := len(base) + isoSectorSize /* boot record */ + isoSectorSize /* catalog */ + len(bios.image)
+	if len(out) != wantLen {
+		t.Errorf("spliceElTorito() output length = %d, want %d", len(out), wantLen)
+	}
+
+	if out[17*isoSectorSize] != 0 {
+		t.Errorf("sector 17 type = %d, want 0 (Boot Record)", out[17*isoSectorSize])
+	}
+	if out[18*isoSectorSize] != 255 {
+		t.Errorf("sector 18 type = %d, want 255 (Terminator, shifted from 17)", out[18*isoSectorSize])
+	}
+
+	pvdOffset := 16 * isoSectorSize
+	rootExtent := format733.read(out[pvdOffset+158:])
+	if rootExtent != 19 {
+		t.Errorf("root directory extent = %d, want 19 (18 shifted by 1)", rootExtent)
+	}
+
+	wantCatalogLBA := uint32(totalSectors + 1)
+	gotCatalogLBA := binary.LittleEndian.Uint32(out[17*isoSectorSize+71 : 17*isoSectorSize+75])
+	if gotCatalogLBA != wantCatalogLBA {
+		t.Errorf("catalog LBA in Boot Record = %d, want %d", gotCatalogLBA, wantCatalogLBA)
+	}
+
+	spaceSize := format733.read(out[pvdOffset+80:])
+	if wantSpaceSize := uint32(wantLen / isoSectorSize); spaceSize != wantSpaceSize {
+		t.Errorf("Volume Space Size = %d, want %d", spaceSize, wantSpaceSize)
+	}
+}
+
+func TestSpliceElToritoRejectsNonSectorAlignedImage(t *testing.T) {
+	if _, err := spliceElTorito(make([]byte, 100), &elToritoBootImage{image: make([]byte, isoSectorSize)}, nil); err == nil {
+		t.Error("spliceElTorito() with a non-sector-aligned image: expected error, got nil")
+	}
+}
+
+func TestSpliceElToritoRequiresBIOSImage(t *testing.T) {
+	base := buildMinimalISO(20)
+	if _, err := spliceElTorito(base, nil, nil); err == nil {
+		t.Error("spliceElTorito() with no BIOS image: expected error, got nil")
+	}
+}
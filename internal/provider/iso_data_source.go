@@ -0,0 +1,247 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kdomanski/iso9660"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces.
+var _ datasource.DataSource = &ISODataSource{}
+
+func NewISODataSource() datasource.DataSource {
+	return &ISODataSource{}
+}
+
+// ISODataSource inspects an existing ISO9660 image on disk, for users
+// migrating pre-baked ISOs into Terraform without regenerating them.
+type ISODataSource struct {
+}
+
+// ISODataSourceModel describes the data source data model.
+type ISODataSourceModel struct {
+	Id                 types.String `tfsdk:"id"`
+	Path               types.String `tfsdk:"path"`
+	VolumeIdentifier   types.String `tfsdk:"volume_identifier"`
+	Files              types.List   `tfsdk:"files"`
+	TotalSize          types.Int64  `tfsdk:"total_size"`
+	IsBootable         types.Bool   `tfsdk:"is_bootable"`
+	UnattendXMLContent types.String `tfsdk:"unattend_xml_content"`
+}
+
+type isoFileModel struct {
+	Path   types.String `tfsdk:"path"`
+	Size   types.Int64  `tfsdk:"size"`
+	SHA256 types.String `tfsdk:"sha256"`
+}
+
+var isoFileAttributeTypes = map[string]attr.Type{
+	"path":   types.StringType,
+	"size":   types.Int64Type,
+	"sha256": types.StringType,
+}
+
+func (d *ISODataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_iso"
+}
+
+func (d *ISODataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: "Reads an existing ISO9660 image and exposes its contents, so pre-baked ISOs can be asserted on without regenerating them.",
+
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "ISO identifier (the path that was read).",
+			},
+			"path": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "Path to the ISO file to inspect.",
+			},
+			"volume_identifier": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Volume identifier from the Primary Volume Descriptor.",
+			},
+			"files": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "Every regular file in the image, with its full path relative to the root directory.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"path": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "File path relative to the root directory.",
+						},
+						"size": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "File size in bytes.",
+						},
+						"sha256": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "SHA-256 of the file's contents.",
+						},
+					},
+				},
+			},
+			"total_size": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Sum of the size of every file in the image.",
+			},
+			"is_bootable": schema.BoolAttribute{
+				Computed:            true,
+				MarkdownDescription: "Whether the image carries an El Torito Boot Record Volume Descriptor.",
+			},
+			"unattend_xml_content": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "Contents of `unattend.xml` or `Autounattend.xml` at the root of the image, if either is present.",
+			},
+		},
+	}
+}
+
+func (d *ISODataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var data ISODataSourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	isoPath := data.Path.ValueString()
+
+	f, err := os.Open(isoPath)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to open %q, got error: %s", isoPath, err))
+		return
+	}
+	defer f.Close()
+
+	image, err := iso9660.OpenImage(f)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse %q as an ISO9660 image, got error: %s", isoPath, err))
+		return
+	}
+
+	label, err := image.Label()
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read volume identifier of %q, got error: %s", isoPath, err))
+		return
+	}
+	data.VolumeIdentifier = types.StringValue(label)
+
+	root, err := image.RootDir()
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read root directory of %q, got error: %s", isoPath, err))
+		return
+	}
+
+	var files []isoFileModel
+	var totalSize int64
+	var unattendXML string
+
+	var walk func(dir *iso9660.File, prefix string) error
+	walk = func(dir *iso9660.File, prefix string) error {
+		children, err := dir.GetChildren()
+		if err != nil {
+			return err
+		}
+		for _, child := range children {
+			childPath := prefix + child.Name()
+			if child.IsDir() {
+				if err := walk(child, childPath+"/"); err != nil {
+					return err
+				}
+				continue
+			}
+
+			contents, err := io.ReadAll(child.Reader())
+			if err != nil {
+				return fmt.Errorf("reading %q: %w", childPath, err)
+			}
+
+			sum := sha256.Sum256(contents)
+			files = append(files, isoFileModel{
+				Path:   types.StringValue(childPath),
+				Size:   types.Int64Value(child.Size()),
+				SHA256: types.StringValue(hex.EncodeToString(sum[:])),
+			})
+			totalSize += child.Size()
+
+			if childPath == "unattend.xml" || childPath == "Autounattend.xml" {
+				unattendXML = string(contents)
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root, ""); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to walk %q, got error: %s", isoPath, err))
+		return
+	}
+
+	filesList, diags := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: isoFileAttributeTypes}, files)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bootable, err := hasElToritoBootRecord(isoPath)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to inspect volume descriptors of %q, got error: %s", isoPath, err))
+		return
+	}
+
+	data.Id = types.StringValue(isoPath)
+	data.Files = filesList
+	data.TotalSize = types.Int64Value(totalSize)
+	data.IsBootable = types.BoolValue(bootable)
+	data.UnattendXMLContent = types.StringValue(unattendXML)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// hasElToritoBootRecord checks whether the volume descriptor set carries a
+// Boot Record Volume Descriptor (type 0), which El Torito uses to point at
+// the boot catalog. Volume descriptors start at sector 16 (offset 32768)
+// and are always 2048 bytes; the type byte is the first byte of each.
+func hasElToritoBootRecord(path string) (bool, error) {
+	const (
+		sectorSize          = 2048
+		volumeDescriptorsAt = 16 * sectorSize
+		bootRecordType      = 0
+		terminatorType      = 255
+	)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 1)
+	for sector := 0; ; sector++ {
+		if _, err := f.ReadAt(buf, int64(volumeDescriptorsAt+sector*sectorSize)); err != nil {
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, err
+		}
+		switch buf[0] {
+		case bootRecordType:
+			return true, nil
+		case terminatorType:
+			return false, nil
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHasElToritoBootRecord(t *testing.T) {
+	t.Run("plain data disc", func(t *testing.T) {
+		base := buildMinimalISO(20)
+		path := writeTempISO(t, base)
+
+		got, err := hasElToritoBootRecord(path)
+		if err != nil {
+			t.Fatalf("hasElToritoBootRecord() error: %v", err)
+		}
+		if got {
+			t.Error("hasElToritoBootRecord() = true, want false for a plain data disc")
+		}
+	})
+
+	t.Run("bootable disc", func(t *testing.T) {
+		base := buildMinimalISO(20)
+		bios := &elToritoBootImage{image: make([]byte, isoSectorSize), noEmulation: true, loadSize: 4}
+		spliced, err := spliceElTorito(base, bios, nil)
+		if err != nil {
+			t.Fatalf("spliceElTorito() error: %v", err)
+		}
+		path := writeTempISO(t, spliced)
+
+		got, err := hasElToritoBootRecord(path)
+		if err != nil {
+			t.Fatalf("hasElToritoBootRecord() error: %v", err)
+		}
+		if !got {
+			t.Error("hasElToritoBootRecord() = false, want true for a spliced, bootable disc")
+		}
+	})
+}
+
+func writeTempISO(t *testing.T, contents []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.iso")
+	if err := os.WriteFile(path, contents, 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
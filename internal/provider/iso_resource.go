@@ -6,13 +6,20 @@ package provider
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/kdomanski/iso9660"
@@ -20,6 +27,12 @@ import (
 	"strings"
 )
 
+// defaultBootLoadSize is the El Torito "sector count" used when no_emulation
+// is set and boot_load_size is left unconfigured: 4 virtual (512-byte)
+// sectors, i.e. one 2048-byte CD-ROM sector, which is what etfsboot.com and
+// efisys.bin both expect to be loaded as.
+const defaultBootLoadSize = 4
+
 // Ensure provider defined types fully satisfy framework interfaces.
 var _ resource.Resource = &UnattendedISOResource{}
 var _ resource.ResourceWithImportState = &UnattendedISOResource{}
@@ -30,19 +43,28 @@ func NewUnattendedISOResource() resource.Resource {
 
 // UnattendedISOResource defines the resource implementation.
 type UnattendedISOResource struct {
+	providerConfig *unattendProviderConfig
 }
 
 // UnattendedISOResourceModel describes the resource data model.
 type UnattendedISOResourceModel struct {
-	Id           types.String `tfsdk:"id"`
-	FileName     types.String `tfsdk:"file_name"`
-	PathOverride types.String `tfsdk:"path_override"`
-	XMLContent   types.String `tfsdk:"xml_content"`
-	ResultPath   types.String `tfsdk:"result_path"`
+	Id               types.String `tfsdk:"id"`
+	FileName         types.String `tfsdk:"file_name"`
+	PathOverride     types.String `tfsdk:"path_override"`
+	XMLContent       types.String `tfsdk:"xml_content"`
+	ResultPath       types.String `tfsdk:"result_path"`
+	BootImagePath    types.String `tfsdk:"boot_image_path"`
+	EFIBootImagePath types.String `tfsdk:"efi_boot_image_path"`
+	BootCatalog      types.String `tfsdk:"boot_catalog"`
+	BootLoadSize     types.Int64  `tfsdk:"boot_load_size"`
+	NoEmulation      types.Bool   `tfsdk:"no_emulation"`
+	BootImageSHA256  types.String `tfsdk:"boot_image_sha256"`
+	SHA256           types.String `tfsdk:"sha256"`
+	SizeBytes        types.Int64  `tfsdk:"size_bytes"`
 }
 
 func (r *UnattendedISOResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
-	resp.TypeName = req.ProviderTypeName + "unattend_iso_file"
+	resp.TypeName = req.ProviderTypeName + "_iso_file"
 }
 
 func (r *UnattendedISOResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
@@ -52,15 +74,21 @@ func (r *UnattendedISOResource) Schema(ctx context.Context, req resource.SchemaR
 
 		Attributes: map[string]schema.Attribute{
 			"path_override": schema.StringAttribute{
-				MarkdownDescription: "Path to write the local ISO file, defaults to OS temp",
+				MarkdownDescription: "Path to write the local ISO file, defaults to OS temp. Changing this forces recreation, since the old file at the previous path is not moved.",
 				Optional:            true,
 				Computed:            true,
 				Default:             stringdefault.StaticString("tmp"),
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"file_name": schema.StringAttribute{
-				MarkdownDescription: "Name for the created ISO file",
+				MarkdownDescription: "Name for the created ISO file. Changing this forces recreation, since the old file under the previous name is not renamed.",
 				Optional:            false,
 				Required:            true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"xml_content": schema.StringAttribute{
 				MarkdownDescription: "XML content for the unattend.xml file.",
@@ -83,6 +111,50 @@ func (r *UnattendedISOResource) Schema(ctx context.Context, req resource.SchemaR
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"boot_image_path": schema.StringAttribute{
+				MarkdownDescription: "Path to a BIOS boot loader payload (e.g. `etfsboot.com`) to embed as an El Torito boot entry. When set, the ISO becomes bootable instead of a plain data disc.",
+				Optional:            true,
+			},
+			"efi_boot_image_path": schema.StringAttribute{
+				MarkdownDescription: "Path to a UEFI boot loader payload (e.g. `efisys.bin`) to embed as a second, UEFI-platform El Torito boot entry alongside `boot_image_path`.",
+				Optional:            true,
+			},
+			"boot_catalog": schema.StringAttribute{
+				MarkdownDescription: "Reserved for a future release; the boot catalog is currently addressed by sector rather than by a path inside the ISO, so this value is accepted but unused.",
+				Optional:            true,
+				Computed:            true,
+				Default:             stringdefault.StaticString("boot.catalog"),
+			},
+			"boot_load_size": schema.Int64Attribute{
+				MarkdownDescription: "Number of 512-byte virtual sectors to load from the boot image at boot time. Defaults to 4 (one 2048-byte sector), which is correct for `etfsboot.com` and `efisys.bin`.",
+				Optional:            true,
+				Computed:            true,
+				Default:             int64default.StaticInt64(defaultBootLoadSize),
+				Validators: []validator.Int64{
+					int64validator.Between(0, 65535),
+				},
+			},
+			"no_emulation": schema.BoolAttribute{
+				MarkdownDescription: "Whether the boot entry uses no-emulation mode (boot the image as-is, the way Windows Setup media does) rather than floppy/hard-disk emulation.",
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+			},
+			"boot_image_sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "SHA-256 of `boot_image_path`, exposed so downstream tooling (Packer, libvirt) can verify the embedded loader without re-reading the ISO.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"sha256": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "SHA-256 of the generated ISO file. Recomputed on every `Read`; a mismatch with the value on disk means the file was tampered with out of band.",
+			},
+			"size_bytes": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Size of the generated ISO file, in bytes.",
+			},
 		},
 	}
 }
@@ -93,6 +165,13 @@ func (r *UnattendedISOResource) Configure(ctx context.Context, req resource.Conf
 		return
 	}
 
+	config, ok := req.ProviderData.(*unattendProviderConfig)
+	if !ok {
+		resp.Diagnostics.AddError("Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected *unattendProviderConfig, got: %T.", req.ProviderData))
+		return
+	}
+	r.providerConfig = config
 }
 
 func (r *UnattendedISOResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
@@ -105,81 +184,140 @@ func (r *UnattendedISOResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := r.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create example, got error: %s", err))
-	//     return
-	// }
-
-	// For the purposes of this example code, hardcoding a response value to
-	// save into the Terraform state.
-	data.Id = types.StringValue("example-id")
+	data.Id = types.StringValue(data.FileName.ValueString())
+	data.ResultPath = types.StringValue(r.resolveResultPath(&data))
 
-	// Write logs using the tflog package
-	// Documentation: https://terraform.io/plugin/log
 	tflog.Trace(ctx, "created a resource")
 
+	if !r.writeISO(ctx, &data, &resp.Diagnostics) {
+		return
+	}
+
+	// Save data into Terraform state
+	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+}
+
+// resolveResultPath computes the on-disk path an ISO should be written to.
+// See unattendProviderConfig.resolveOutputPath for the path_override rules.
+func (r *UnattendedISOResource) resolveResultPath(data *UnattendedISOResourceModel) string {
+	return r.providerConfig.resolveOutputPath(data.PathOverride.ValueString(), data.FileName.ValueString())
+}
+
+// writeISO renders the ISO described by data (unattend.xml plus any El
+// Torito boot entries), writes it to data.ResultPath, and populates
+// data.SHA256 / data.SizeBytes / data.BootImageSHA256. It is shared by
+// Create and Update, which differ only in whether ResultPath may change.
+func (r *UnattendedISOResource) writeISO(ctx context.Context, data *UnattendedISOResourceModel, diags *diag.Diagnostics) bool {
 	isoWriter, err := iso9660.NewWriter()
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to start ISO Writer, got error: %s", err))
-		return
+		diags.AddError("Client Error", fmt.Sprintf("Unable to start ISO Writer, got error: %s", err))
+		return false
 	}
 	defer func(isoWriter *iso9660.ImageWriter) {
 		err := isoWriter.Cleanup()
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error in ISO Writer, got error: %s", err))
-			return
+			diags.AddError("Client Error", fmt.Sprintf("Error in ISO Writer, got error: %s", err))
 		}
 	}(isoWriter)
 
-	if data.XMLContent.String() != "" {
-		err = isoWriter.AddFile(strings.NewReader(data.XMLContent.String()), "unattend.xml")
+	if data.XMLContent.ValueString() != "" {
+		xmlContent, err := r.providerConfig.renderTemplate("xml_content", data.XMLContent.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error adding file to ISO, got error: %s", err))
-			return
+			diags.AddError("Client Error", fmt.Sprintf("Error rendering xml_content template, got error: %s", err))
+			return false
 		}
-	}
 
-	var b bytes.Buffer
-	err = isoWriter.WriteTo(&b, "unattend")
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error writing to ISO, got error: %s", err))
-		return
+		err = isoWriter.AddFile(strings.NewReader(xmlContent), "unattend.xml")
+		if err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Error adding file to ISO, got error: %s", err))
+			return false
+		}
 	}
 
-	// Calculate the ISO sha256 sum
-	//sum := fmt.Sprintf("%x", sha256.Sum256(b.Bytes()))
-
-	if data.PathOverride.String() != "tmp" {
-		file, err := os.CreateTemp("/tmp", data.FileName.String())
+	var bios *elToritoBootImage
+	if !data.BootImagePath.IsNull() && data.BootImagePath.ValueString() != "" {
+		bootImage, err := os.ReadFile(data.BootImagePath.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error creating tmp file, got error: %s", err))
-			return
+			diags.AddError("Client Error", fmt.Sprintf("Unable to read boot_image_path %q: %s", data.BootImagePath.ValueString(), err))
+			return false
 		}
-		_, err = file.Write(b.Bytes())
-		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error writing to tmp file, got error: %s", err))
-			return
+		if len(bootImage)%2048 != 0 {
+			diags.AddAttributeError(path.Root("boot_image_path"), "Invalid Boot Image",
+				fmt.Sprintf("boot image %q is %d bytes, which is not a multiple of the 2048-byte CD-ROM sector size", data.BootImagePath.ValueString(), len(bootImage)))
+			return false
+		}
+
+		sum := sha256.Sum256(bootImage)
+		data.BootImageSHA256 = types.StringValue(hex.EncodeToString(sum[:]))
+
+		bios = &elToritoBootImage{
+			image:       bootImage,
+			noEmulation: data.NoEmulation.ValueBool(),
+			loadSize:    uint16(data.BootLoadSize.ValueInt64()),
 		}
-		data.ResultPath = types.StringValue(file.Name())
 	} else {
-		file, err := os.Create(data.PathOverride.String() + data.FileName.String())
+		// boot_image_sha256 is Computed; it must be resolved to a known
+		// value (even if null) on every Create/Update, or Terraform reports
+		// an inconsistent result after apply.
+		data.BootImageSHA256 = types.StringNull()
+
+		if !data.EFIBootImagePath.IsNull() && data.EFIBootImagePath.ValueString() != "" {
+			diags.AddAttributeError(path.Root("efi_boot_image_path"), "Missing BIOS Boot Image",
+				"efi_boot_image_path requires boot_image_path to also be set, so BIOS firmware can still boot the media.")
+			return false
+		}
+	}
+
+	var efi *elToritoBootImage
+	if bios != nil && !data.EFIBootImagePath.IsNull() && data.EFIBootImagePath.ValueString() != "" {
+		efiImage, err := os.ReadFile(data.EFIBootImagePath.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error creating file, got error: %s", err))
-			return
+			diags.AddError("Client Error", fmt.Sprintf("Unable to read efi_boot_image_path %q: %s", data.EFIBootImagePath.ValueString(), err))
+			return false
+		}
+		if len(efiImage)%2048 != 0 {
+			diags.AddAttributeError(path.Root("efi_boot_image_path"), "Invalid Boot Image",
+				fmt.Sprintf("boot image %q is %d bytes, which is not a multiple of the 2048-byte CD-ROM sector size", data.EFIBootImagePath.ValueString(), len(efiImage)))
+			return false
+		}
+		efi = &elToritoBootImage{
+			image:       efiImage,
+			noEmulation: bios.noEmulation,
+			loadSize:    bios.loadSize,
 		}
-		_, err = file.Write(b.Bytes())
+	}
+
+	var b bytes.Buffer
+	err = isoWriter.WriteTo(&b, r.providerConfig.volumeLabel("unattend"))
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Error writing to ISO, got error: %s", err))
+		return false
+	}
+
+	isoBytes := b.Bytes()
+	if bios != nil {
+		isoBytes, err = spliceElTorito(isoBytes, bios, efi)
 		if err != nil {
-			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Error writing file, got error: %s", err))
-			return
+			diags.AddError("Client Error", fmt.Sprintf("Unable to splice El Torito boot catalog into ISO, got error: %s", err))
+			return false
 		}
-		data.ResultPath = types.StringValue(file.Name())
 	}
 
-	// Save data into Terraform state
-	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
+	resultPath := data.ResultPath.ValueString()
+	if err := os.WriteFile(resultPath, isoBytes, 0o644); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Error writing ISO to %q, got error: %s", resultPath, err))
+		return false
+	}
+
+	sum, err := r.providerConfig.hashContent(isoBytes)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Error hashing ISO, got error: %s", err))
+		return false
+	}
+	data.SHA256 = types.StringValue(sum)
+	data.SizeBytes = types.Int64Value(int64(len(isoBytes)))
+
+	return true
 }
 
 func (r *UnattendedISOResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -192,13 +330,24 @@ func (r *UnattendedISOResource) Read(ctx context.Context, req resource.ReadReque
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := r.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read example, got error: %s", err))
-	//     return
-	// }
+	result, err := r.providerConfig.readBackISO(data.ResultPath.ValueString(), data.SHA256.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+		return
+	}
+	if result.Missing {
+		tflog.Trace(ctx, "ISO file is gone, removing from state", map[string]interface{}{"result_path": data.ResultPath.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if result.Drifted {
+		tflog.Trace(ctx, "ISO file hash drifted, removing from state", map[string]interface{}{"result_path": data.ResultPath.ValueString()})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	data.SHA256 = types.StringValue(result.SHA256)
+	data.SizeBytes = types.Int64Value(result.Size)
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -214,13 +363,11 @@ func (r *UnattendedISOResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := r.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update example, got error: %s", err))
-	//     return
-	// }
+	// path_override and file_name carry RequiresReplace plan modifiers, so
+	// ResultPath is unchanged here; only the ISO contents are regenerated.
+	if !r.writeISO(ctx, &data, &resp.Diagnostics) {
+		return
+	}
 
 	// Save updated data into Terraform state
 	resp.Diagnostics.Append(resp.State.Set(ctx, &data)...)
@@ -236,13 +383,10 @@ func (r *UnattendedISOResource) Delete(ctx context.Context, req resource.DeleteR
 		return
 	}
 
-	// If applicable, this is a great opportunity to initialize any necessary
-	// provider client data and make a call using it.
-	// httpResp, err := r.client.Do(httpReq)
-	// if err != nil {
-	//     resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete example, got error: %s", err))
-	//     return
-	// }
+	if err := removeISOFile(data.ResultPath.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", err.Error())
+		return
+	}
 }
 
 func (r *UnattendedISOResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
@@ -5,10 +5,14 @@ package provider
 
 import (
 	"context"
+	"fmt"
+
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 // Ensure UnattendISOProvider satisfies various provider interfaces.
@@ -24,6 +28,10 @@ type UnattendISOProvider struct {
 
 // UnattendISOProviderModel describes the provider data model.
 type UnattendISOProviderModel struct {
+	DefaultOutputDir types.String `tfsdk:"default_output_dir"`
+	HashAlgorithm    types.String `tfsdk:"hash_algorithm"`
+	TemplateVars     types.Map    `tfsdk:"template_vars"`
+	ISOVolumePrefix  types.String `tfsdk:"iso_volume_prefix"`
 }
 
 func (p *UnattendISOProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -33,21 +41,78 @@ func (p *UnattendISOProvider) Metadata(ctx context.Context, req provider.Metadat
 
 func (p *UnattendISOProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
 	resp.Schema = schema.Schema{
-		Attributes: map[string]schema.Attribute{},
+		Attributes: map[string]schema.Attribute{
+			"default_output_dir": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Directory used when a resource omits `path_override`. Defaults to the OS temp directory.",
+			},
+			"hash_algorithm": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Algorithm used for every resource's `sha256` output: `sha256` (default), `sha512`, or `none` to skip hashing.",
+			},
+			"template_vars": schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Variables merged into every rendered XML document via Go `text/template` before it's embedded in an ISO.",
+			},
+			"iso_volume_prefix": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Prefix prepended to every resource's ISO volume label.",
+			},
+		},
 	}
 }
 
 func (p *UnattendISOProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var data UnattendISOProviderModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	config := &unattendProviderConfig{
+		defaultOutputDir: data.DefaultOutputDir.ValueString(),
+		hashAlgorithm:    data.HashAlgorithm.ValueString(),
+		isoVolumePrefix:  data.ISOVolumePrefix.ValueString(),
+	}
+
+	switch config.hashAlgorithm {
+	case "", hashAlgorithmSHA256, hashAlgorithmSHA512, hashAlgorithmNone:
+	default:
+		resp.Diagnostics.AddAttributeError(
+			path.Root("hash_algorithm"),
+			"Invalid hash_algorithm",
+			fmt.Sprintf("hash_algorithm must be one of \"sha256\", \"sha512\", or \"none\", got %q", config.hashAlgorithm),
+		)
+		return
+	}
+
+	if !data.TemplateVars.IsNull() {
+		vars := make(map[string]string, len(data.TemplateVars.Elements()))
+		resp.Diagnostics.Append(data.TemplateVars.ElementsAs(ctx, &vars, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		config.templateVars = vars
+	}
+
+	resp.ResourceData = config
+	resp.DataSourceData = config
 }
 
 func (p *UnattendISOProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewUnattendedISOResource,
+		NewAutounattendXMLResource,
+		NewCloudInitISOResource,
 	}
 }
 
 func (p *UnattendISOProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
-	return []func() datasource.DataSource{}
+	return []func() datasource.DataSource{
+		NewISODataSource,
+	}
 }
 
 func New(version string) func() provider.Provider {